@@ -17,19 +17,48 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/viglesiasce/cleanpvcs/pkg/cleaner"
 	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/yaml"
 )
 
+const defaultKinds = "deployments,statefulsets,pvcs"
+
+// DeletionResult captures what a single cleaner found and removed (or, in
+// dry-run mode, would have removed) for one resource kind.
+type DeletionResult struct {
+	Kind    string   `json:"kind" yaml:"kind"`
+	Deleted []string `json:"deleted" yaml:"deleted"`
+}
+
+// NamespaceReport aggregates the deletion results for a single orphaned
+// namespace, one entry per kind that was cleaned.
+type NamespaceReport struct {
+	Namespace string           `json:"namespace" yaml:"namespace"`
+	Results   []DeletionResult `json:"results" yaml:"results"`
+}
+
+// Report is the top-level structured output produced by a run, suitable for
+// emitting as text, JSON, or YAML.
+type Report struct {
+	DryRun     bool              `json:"dryRun" yaml:"dryRun"`
+	Namespaces []NamespaceReport `json:"namespaces" yaml:"namespaces"`
+}
+
 func main() {
 	var kubeconfig *string
 	if home := homeDir(); home != "" {
@@ -37,8 +66,46 @@ func main() {
 	} else {
 		kubeconfig = flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
 	}
+	dryRun := flag.Bool("dry-run", false, "enumerate orphaned-namespace PVCs and workloads without making any mutating API calls")
+	output := flag.String("output", "text", "report format: text|json|yaml")
+	kinds := flag.String("kinds", defaultKinds, fmt.Sprintf("comma-separated resource kinds to clean, any of: %s", strings.Join(cleaner.Names(), ",")))
+	force := flag.Bool("force", false, "clear finalizers and relax Retain reclaim policies on PVCs/PVs wedged in an orphaned namespace")
+	forceTimeout := flag.Duration("force-timeout", 30*time.Second, "how long each --force attempt waits for a finalizer-blocked resource to disappear; a timed-out attempt is retried like any other failed delete")
+	namespaceSelector := flag.String("namespace-selector", "", "label selector matched against each orphaned namespace's name (via kubernetes.io/metadata.name)")
+	labelSelector := flag.String("label-selector", "", "label selector passed to the PVC list call; only PVCs matching it are considered")
+	includeNamespace := flag.String("include-namespace", "", "comma-separated allowlist of namespace names; if set, only these are eligible")
+	excludeNamespace := flag.String("exclude-namespace", "", "comma-separated denylist of namespace names to skip")
+	protectedNamespaces := flag.String("protected-namespaces", strings.Join(defaultProtectedNamespaces, ","), "comma-separated namespaces that are never recreated or deleted, even if orphaned")
+	yes := flag.Bool("yes", false, "skip the confirmation prompt before recreating and cleaning up orphaned namespaces")
+	parallelism := flag.Int("parallelism", 4, "number of orphaned namespaces to process concurrently")
+	qps := flag.Float64("qps", 10, "maximum API server queries per second")
+	burst := flag.Int("burst", 20, "maximum API server query burst above --qps")
 	flag.Parse()
 
+	if *parallelism < 1 {
+		fmt.Fprintln(os.Stderr, "--parallelism must be at least 1")
+		os.Exit(1)
+	}
+	if *qps <= 0 {
+		fmt.Fprintln(os.Stderr, "--qps must be greater than 0")
+		os.Exit(1)
+	}
+	if *burst <= 0 {
+		fmt.Fprintln(os.Stderr, "--burst must be greater than 0")
+		os.Exit(1)
+	}
+
+	if *output != "text" && *output != "json" && *output != "yaml" {
+		fmt.Fprintf(os.Stderr, "unsupported --output %q: must be one of text|json|yaml\n", *output)
+		os.Exit(1)
+	}
+
+	scope, err := newScopeFilter(*namespaceSelector, *includeNamespace, *excludeNamespace, *protectedNamespaces)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
 	// use the current context in kubeconfig
 	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
 	if err != nil {
@@ -51,112 +118,183 @@ func main() {
 		panic(err.Error())
 	}
 
-	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(metav1.ListOptions{})
+	cleaners, err := cleaner.Build(clientset, splitCSV(*kinds))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error building cleaners: %v\n", err)
+		os.Exit(1)
+	}
+
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(metav1.ListOptions{LabelSelector: *labelSelector})
 	if err != nil {
 		panic(err.Error())
 	}
 	fmt.Printf("There are %d PVCs in the cluster\n", len(pvcs.Items))
 
+	var targets []string
 	namespacesSeen := make(map[string]bool)
 	for _, pvc := range pvcs.Items {
 		if namespacesSeen[pvc.Namespace] {
-			// Skip namespaces that we've already worked through
-			fmt.Printf("Skipping namespace: %v\n", pvc.Namespace)
 			continue
 		}
+		namespacesSeen[pvc.Namespace] = true
 		namespace, err := clientset.CoreV1().Namespaces().Get(pvc.Namespace, metav1.GetOptions{})
 		// Check if namespace is not found
 		// These are the PVCs that are wedged
-		if err != nil && strings.Contains(err.Error(), "not found") {
-			createNamespace(clientset, pvc.Namespace)
-			deleteDeployments(clientset, pvc.Namespace)
-			deleteStatefulSets(clientset, pvc.Namespace)
-			deletePVCs(clientset, pvc.Namespace)
-			deleteNamespace(clientset, pvc.Namespace)
-			// Mark the namespace as seen
-			namespacesSeen[pvc.Namespace] = true
-		} else {
+		if err == nil || !apierrors.IsNotFound(err) {
 			fmt.Printf("Skipping PVC: %v:%v\n", namespace.Name, pvc.Name)
+			continue
+		}
+		if !scope.allows(pvc.Namespace) {
+			fmt.Printf("Skipping namespace (out of scope): %v\n", pvc.Namespace)
+			continue
 		}
+		targets = append(targets, pvc.Namespace)
 	}
-}
 
-func createNamespace(clientset *kubernetes.Clientset, name string) {
-	newNamespace := apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
-	fmt.Printf("Creating Namespace: %s\n", name)
-	namespace, err := clientset.CoreV1().Namespaces().Create(&newNamespace)
-	if err != nil {
-		panic(err.Error())
+	if !*dryRun && !confirmNamespaces(targets, *yes) {
+		fmt.Println("Aborted.")
+		os.Exit(1)
 	}
-	fmt.Printf("Namespace created: %s\n", namespace.Name)
-}
 
-func deleteNamespace(clientset *kubernetes.Clientset, name string) {
-	fmt.Printf("Deleting Namespace: %s\n", name)
-	err := clientset.CoreV1().Namespaces().Delete(name, &metav1.DeleteOptions{})
-	if err != nil {
-		panic(err.Error())
+	limiter := flowcontrol.NewTokenBucketRateLimiter(float32(*qps), *burst)
+	reports, errs := processNamespacesConcurrently(clientset, cleaners, targets, *dryRun, *force, *forceTimeout, *parallelism, limiter)
+	report := Report{DryRun: *dryRun, Namespaces: reports}
+
+	if err := printReport(report, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "error printing report: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(errs) > 0 {
+		for namespace, err := range errs {
+			fmt.Fprintf(os.Stderr, "error processing namespace %s: %v\n", namespace, err)
+		}
+		os.Exit(1)
 	}
-	fmt.Printf("Deleted namespace: %s\n", name)
 }
 
-func deleteDeployments(clientset *kubernetes.Clientset, namespace string) {
-	fmt.Printf("Deleting deployments in namespace: %s\n", namespace)
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{})
-	if err != nil {
-		panic(err.Error())
+// processOrphanedNamespace recreates a deleted-but-still-referenced namespace
+// (unless dryRun is set, in which case it only enumerates what would be
+// touched), runs every registered cleaner against it in order, and returns a
+// report describing what happened.
+func processOrphanedNamespace(clientset kubernetes.Interface, cleaners []cleaner.ResourceCleaner, name string, dryRun, force bool, forceTimeout time.Duration, limiter flowcontrol.RateLimiter) (NamespaceReport, error) {
+	nsReport := NamespaceReport{Namespace: name}
+
+	if !dryRun {
+		if err := withRetry(limiter, func() error { return createNamespace(clientset, name) }); err != nil {
+			return nsReport, err
+		}
 	}
 
-	for _, deployment := range deployments.Items {
-		fmt.Printf("Deleting v1Apps deployment: %s:%s\n", namespace, deployment.Name)
-		err := clientset.AppsV1().Deployments(namespace).Delete(deployment.Name, &metav1.DeleteOptions{})
+	for _, c := range cleaners {
+		result, err := runCleaner(c, name, dryRun, force, forceTimeout, limiter)
 		if err != nil {
-			panic(err)
+			return nsReport, err
 		}
+		nsReport.Results = append(nsReport.Results, result)
 	}
-	deploymentsBeta, err := clientset.ExtensionsV1beta1().Deployments(namespace).List(metav1.ListOptions{})
-	for _, deployment := range deploymentsBeta.Items {
-		fmt.Printf("Deleting v1Beta deployment: %s:%s\n", namespace, deployment.Name)
-		err := clientset.ExtensionsV1beta1().Deployments(namespace).Delete(deployment.Name, &metav1.DeleteOptions{})
-		if err != nil {
-			panic(err)
+
+	if !dryRun {
+		if err := withRetry(limiter, func() error { return deleteNamespace(clientset, name) }); err != nil {
+			return nsReport, err
 		}
 	}
-	fmt.Printf("Deleted deployments in namespace: %s\n", namespace)
+
+	return nsReport, nil
 }
 
-func deleteStatefulSets(clientset *kubernetes.Clientset, namespace string) {
-	fmt.Printf("Deleting statefulsets in namespace: %s\n", namespace)
-	statefulsets, err := clientset.AppsV1().StatefulSets(namespace).List(metav1.ListOptions{})
-	if err != nil {
-		panic(err.Error())
+// runCleaner lists every resource of c's kind in namespace and deletes each
+// one, unless dryRun is set, in which case it only reports what would be
+// deleted. When force is set and c supports it, wedged resources have their
+// finalizers cleared and their Retain reclaim policy relaxed instead of
+// being left to block forever. Every list/delete call is rate-limited by
+// limiter and retried with exponential backoff.
+func runCleaner(c cleaner.ResourceCleaner, namespace string, dryRun, force bool, forceTimeout time.Duration, limiter flowcontrol.RateLimiter) (DeletionResult, error) {
+	result := DeletionResult{Kind: c.Kind()}
+	fmt.Printf("Cleaning %ss in namespace: %s\n", c.Kind(), namespace)
+
+	var resources []cleaner.Resource
+	if err := withRetry(limiter, func() error {
+		var err error
+		resources, err = c.List(namespace)
+		return err
+	}); err != nil {
+		return result, err
+	}
+
+	forceDeleter, canForce := c.(cleaner.ForceDeleter)
+
+	for _, resource := range resources {
+		result.Deleted = append(result.Deleted, resource.Name)
+		if dryRun {
+			fmt.Printf("Would delete %s: %s:%s\n", c.Kind(), namespace, resource.Name)
+			continue
+		}
+		if force && canForce {
+			fmt.Printf("Force deleting %s: %s:%s\n", c.Kind(), namespace, resource.Name)
+			if err := withRetry(limiter, func() error { return forceDeleter.ForceDelete(namespace, resource.Name, forceTimeout) }); err != nil {
+				return result, err
+			}
+			continue
+		}
+		fmt.Printf("Deleting %s: %s:%s\n", c.Kind(), namespace, resource.Name)
+		if err := withRetry(limiter, func() error { return c.Delete(namespace, resource.Name) }); err != nil {
+			return result, err
+		}
 	}
 
-	for _, statefulset := range statefulsets.Items {
-		fmt.Printf("Deleting v1Apps statefulset: %s:%s\n", namespace, statefulset.Name)
-		err := clientset.AppsV1().StatefulSets(namespace).Delete(statefulset.Name, &metav1.DeleteOptions{})
+	fmt.Printf("Cleaned %ss in namespace: %s\n", c.Kind(), namespace)
+	return result, nil
+}
+
+func printReport(report Report, output string) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(report, "", "  ")
 		if err != nil {
-			panic(err)
+			return err
+		}
+		fmt.Println(string(b))
+	case "yaml":
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+	default:
+		verb := "Deleted"
+		if report.DryRun {
+			verb = "Would delete"
+		}
+		for _, ns := range report.Namespaces {
+			fmt.Printf("Namespace: %s\n", ns.Namespace)
+			for _, result := range ns.Results {
+				fmt.Printf("  %s %d %s: %s\n", verb, len(result.Deleted), result.Kind, strings.Join(result.Deleted, ", "))
+			}
 		}
 	}
-	fmt.Printf("Deleted statefulsets in namespace: %s\n", namespace)
+	return nil
 }
 
-func deletePVCs(clientset *kubernetes.Clientset, namespace string) {
-	fmt.Printf("Deleting PVC in namespace: %v\n", namespace)
-	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{})
+func createNamespace(clientset kubernetes.Interface, name string) error {
+	newNamespace := apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	fmt.Printf("Creating Namespace: %s\n", name)
+	namespace, err := clientset.CoreV1().Namespaces().Create(&newNamespace)
 	if err != nil {
-		panic(err.Error())
+		return err
 	}
+	fmt.Printf("Namespace created: %s\n", namespace.Name)
+	return nil
+}
 
-	for _, pvc := range pvcs.Items {
-		fmt.Printf("Deleting v1Apps pvc: %s:%s\n", namespace, pvc.Name)
-		err := clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(pvc.Name, &metav1.DeleteOptions{})
-		if err != nil {
-			panic(err)
-		}
+func deleteNamespace(clientset kubernetes.Interface, name string) error {
+	fmt.Printf("Deleting Namespace: %s\n", name)
+	err := clientset.CoreV1().Namespaces().Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		return err
 	}
-	fmt.Printf("Deleted PVC: %v\n", namespace)
+	fmt.Printf("Deleted namespace: %s\n", name)
+	return nil
 }
 
 func homeDir() string {
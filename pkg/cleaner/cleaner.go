@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cleaner defines a pluggable, per-kind Kubernetes resource cleaner
+// and a registry of built-in implementations. Adding support for a new kind
+// means adding a new file to this package and registering it in an init();
+// callers never need to touch the cleanup loop itself.
+package cleaner
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// pollInterval is how often force-delete implementations recheck a
+// resource while waiting for finalizers to clear. A var, not a const, so
+// tests can shrink it instead of waiting out the real interval.
+var pollInterval = 2 * time.Second
+
+// Resource is a minimal, kind-agnostic description of an object a
+// ResourceCleaner has found in a namespace.
+type Resource struct {
+	Name string
+}
+
+// ResourceCleaner knows how to list and delete every object of a single
+// Kubernetes kind within a namespace.
+type ResourceCleaner interface {
+	// Kind returns the human-readable resource kind this cleaner manages,
+	// e.g. "Deployment".
+	Kind() string
+	// List returns every resource of this kind in namespace.
+	List(namespace string) ([]Resource, error)
+	// Delete removes the named resource of this kind from namespace.
+	Delete(namespace, name string) error
+}
+
+// ForceDeleter is implemented by cleaners that know how to resolve a
+// resource wedged by a finalizer or a retained backing volume. The cleanup
+// loop calls ForceDelete instead of Delete when --force is set and the
+// cleaner for that kind supports it.
+type ForceDeleter interface {
+	ResourceCleaner
+	// ForceDelete removes the named resource, clearing any finalizers
+	// blocking its deletion, and blocks until it is gone or timeout
+	// elapses.
+	ForceDelete(namespace, name string, timeout time.Duration) error
+}
+
+// Factory builds a ResourceCleaner bound to the given clientset.
+type Factory func(clientset kubernetes.Interface) ResourceCleaner
+
+var (
+	order     []string
+	factories = map[string]Factory{}
+)
+
+// Register adds a named cleaner factory to the registry. Built-in cleaners
+// call this from their own init(); the order cleaners are registered in is
+// the order the cleanup loop processes them.
+func Register(name string, factory Factory) {
+	if _, exists := factories[name]; !exists {
+		order = append(order, name)
+	}
+	factories[name] = factory
+}
+
+// Names returns every registered kind name, in registration order.
+func Names() []string {
+	names := make([]string, len(order))
+	copy(names, order)
+	return names
+}
+
+// Build returns the ResourceCleaners for the requested kind names, bound to
+// clientset and in registration order (not the order names was given in).
+// It returns an error if any requested name is not registered.
+func Build(clientset kubernetes.Interface, names []string) ([]ResourceCleaner, error) {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := factories[name]; !ok {
+			return nil, fmt.Errorf("unknown resource kind %q, must be one of %v", name, Names())
+		}
+		selected[name] = true
+	}
+
+	var cleaners []ResourceCleaner
+	for _, name := range order {
+		if selected[name] {
+			cleaners = append(cleaners, factories[name](clientset))
+		}
+	}
+	return cleaners, nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func init() {
+	Register("configmaps", func(clientset kubernetes.Interface) ResourceCleaner {
+		return &configMapCleaner{clientset: clientset}
+	})
+}
+
+type configMapCleaner struct {
+	clientset kubernetes.Interface
+}
+
+func (c *configMapCleaner) Kind() string {
+	return "ConfigMap"
+}
+
+func (c *configMapCleaner) List(namespace string) ([]Resource, error) {
+	configMaps, err := c.clientset.CoreV1().ConfigMaps(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, configMap := range configMaps.Items {
+		resources = append(resources, Resource{Name: configMap.Name})
+	}
+	return resources, nil
+}
+
+func (c *configMapCleaner) Delete(namespace, name string) error {
+	return c.clientset.CoreV1().ConfigMaps(namespace).Delete(name, &metav1.DeleteOptions{})
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func init() {
+	Register("cronjobs", func(clientset kubernetes.Interface) ResourceCleaner {
+		return &cronJobCleaner{clientset: clientset}
+	})
+}
+
+type cronJobCleaner struct {
+	clientset kubernetes.Interface
+}
+
+func (c *cronJobCleaner) Kind() string {
+	return "CronJob"
+}
+
+func (c *cronJobCleaner) List(namespace string) ([]Resource, error) {
+	cronJobs, err := c.clientset.BatchV1beta1().CronJobs(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, cronJob := range cronJobs.Items {
+		resources = append(resources, Resource{Name: cronJob.Name})
+	}
+	return resources, nil
+}
+
+func (c *cronJobCleaner) Delete(namespace, name string) error {
+	return c.clientset.BatchV1beta1().CronJobs(namespace).Delete(name, &metav1.DeleteOptions{})
+}
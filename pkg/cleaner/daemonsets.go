@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func init() {
+	Register("daemonsets", func(clientset kubernetes.Interface) ResourceCleaner {
+		return &daemonSetCleaner{clientset: clientset}
+	})
+}
+
+type daemonSetCleaner struct {
+	clientset kubernetes.Interface
+}
+
+func (c *daemonSetCleaner) Kind() string {
+	return "DaemonSet"
+}
+
+func (c *daemonSetCleaner) List(namespace string) ([]Resource, error) {
+	daemonSets, err := c.clientset.AppsV1().DaemonSets(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, daemonSet := range daemonSets.Items {
+		resources = append(resources, Resource{Name: daemonSet.Name})
+	}
+	return resources, nil
+}
+
+func (c *daemonSetCleaner) Delete(namespace, name string) error {
+	return c.clientset.AppsV1().DaemonSets(namespace).Delete(name, &metav1.DeleteOptions{})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func init() {
+	Register("deployments", func(clientset kubernetes.Interface) ResourceCleaner {
+		return &deploymentCleaner{clientset: clientset}
+	})
+}
+
+// deploymentCleaner cleans up Deployments, checking both the apps/v1 and
+// extensions/v1beta1 API groups so that clusters still carrying
+// pre-1.16-style Deployments are handled too.
+type deploymentCleaner struct {
+	clientset kubernetes.Interface
+}
+
+func (c *deploymentCleaner) Kind() string {
+	return "Deployment"
+}
+
+func (c *deploymentCleaner) List(namespace string) ([]Resource, error) {
+	var resources []Resource
+	seen := make(map[string]bool)
+
+	deployments, err := c.clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		seen[deployment.Name] = true
+		resources = append(resources, Resource{Name: deployment.Name})
+	}
+
+	// extensions/v1beta1 re-exposes the same underlying objects as
+	// apps/v1 on clusters where both are still served, so skip anything
+	// already seen there instead of reporting it twice.
+	deploymentsBeta, err := c.clientset.ExtensionsV1beta1().Deployments(namespace).List(metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		// extensions/v1beta1 is not served on this cluster.
+		return resources, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deploymentsBeta.Items {
+		if seen[deployment.Name] {
+			continue
+		}
+		resources = append(resources, Resource{Name: deployment.Name})
+	}
+
+	return resources, nil
+}
+
+func (c *deploymentCleaner) Delete(namespace, name string) error {
+	err := c.clientset.AppsV1().Deployments(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	return c.clientset.ExtensionsV1beta1().Deployments(namespace).Delete(name, &metav1.DeleteOptions{})
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	pvcGVR = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	pvGVR  = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}
+)
+
+// withFastPollInterval shrinks pollInterval for the duration of a test, so
+// a ForceDelete test that has to loop doesn't actually wait out the real
+// 2-second interval.
+func withFastPollInterval(t *testing.T) {
+	t.Helper()
+	original := pollInterval
+	pollInterval = time.Millisecond
+	t.Cleanup(func() { pollInterval = original })
+}
+
+// blockDeleteWhileFinalized makes clientset's fake Delete for gvr a no-op
+// whenever the target still carries finalizers, instead of the fake
+// clientset's default of removing it unconditionally. This mirrors a real
+// API server, which defers deleting a finalized object until its
+// finalizers are cleared.
+func blockDeleteWhileFinalized(clientset *fake.Clientset, gvr schema.GroupVersionResource) {
+	clientset.PrependReactor("delete", gvr.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		da := action.(k8stesting.DeleteAction)
+		obj, err := clientset.Tracker().Get(gvr, da.GetNamespace(), da.GetName())
+		if err != nil {
+			return false, nil, nil
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil || len(accessor.GetFinalizers()) == 0 {
+			return false, nil, nil
+		}
+		return true, obj, nil
+	})
+}
+
+// deleteOnFinalizersCleared removes gvr's target from the tracker as soon
+// as an update clears its last finalizer, completing the deletion that
+// blockDeleteWhileFinalized deferred.
+func deleteOnFinalizersCleared(clientset *fake.Clientset, gvr schema.GroupVersionResource) {
+	clientset.PrependReactor("update", gvr.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ua := action.(k8stesting.UpdateAction)
+		obj := ua.GetObject()
+		accessor, err := meta.Accessor(obj)
+		if err != nil || len(accessor.GetFinalizers()) > 0 {
+			return false, nil, nil
+		}
+		if err := clientset.Tracker().Update(gvr, obj, accessor.GetNamespace()); err != nil {
+			return true, nil, err
+		}
+		if err := clientset.Tracker().Delete(gvr, accessor.GetNamespace(), accessor.GetName()); err != nil {
+			return true, nil, err
+		}
+		return true, obj, nil
+	})
+}
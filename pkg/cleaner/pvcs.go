@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func init() {
+	Register("pvcs", func(clientset kubernetes.Interface) ResourceCleaner {
+		return &pvcCleaner{clientset: clientset}
+	})
+}
+
+type pvcCleaner struct {
+	clientset kubernetes.Interface
+}
+
+func (c *pvcCleaner) Kind() string {
+	return "PersistentVolumeClaim"
+}
+
+func (c *pvcCleaner) List(namespace string) ([]Resource, error) {
+	pvcs, err := c.clientset.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, pvc := range pvcs.Items {
+		resources = append(resources, Resource{Name: pvc.Name})
+	}
+	return resources, nil
+}
+
+func (c *pvcCleaner) Delete(namespace, name string) error {
+	return c.clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+// ForceDelete deletes the PVC and then resolves the two reasons a PVC
+// typically gets wedged in an orphaned namespace: the kubernetes.io/pvc-
+// protection finalizer, and a bound PersistentVolume with a Retain reclaim
+// policy that would otherwise survive and go Released instead of being
+// removed. It blocks until the PVC is gone or timeout elapses.
+func (c *pvcCleaner) ForceDelete(namespace, name string, timeout time.Duration) error {
+	pvc, err := c.clientset.CoreV1().PersistentVolumeClaims(namespace).Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	volumeName := pvc.Spec.VolumeName
+
+	if err := c.Delete(namespace, name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if volumeName != "" {
+		if err := (&pvCleaner{clientset: c.clientset}).allowDelete(volumeName); err != nil {
+			return fmt.Errorf("relaxing reclaim policy for PersistentVolume %s: %w", volumeName, err)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		pvc, err := c.clientset.CoreV1().PersistentVolumeClaims(namespace).Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(pvc.Finalizers) > 0 {
+			// kubernetes.io/pvc-protection (and any other finalizer left
+			// on a PVC in an orphaned namespace) will never be cleared by
+			// its owning controller, so --force clears the whole list.
+			pvc.Finalizers = nil
+			if _, err := c.clientset.CoreV1().PersistentVolumeClaims(namespace).Update(pvc); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for PersistentVolumeClaim %s:%s to be deleted", namespace, name)
+		}
+		time.Sleep(pollInterval)
+	}
+}
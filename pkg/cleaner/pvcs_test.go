@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPVCCleanerForceDeleteNormalPath(t *testing.T) {
+	withFastPollInterval(t)
+	clientset := fake.NewSimpleClientset(
+		&apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "payments"},
+			Spec:       apiv1.PersistentVolumeClaimSpec{VolumeName: "pv-data"},
+		},
+		&apiv1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+			Spec: apiv1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: apiv1.PersistentVolumeReclaimRetain,
+				ClaimRef:                      &apiv1.ObjectReference{Namespace: "payments", Name: "data"},
+			},
+		},
+	)
+
+	c := &pvcCleaner{clientset: clientset}
+	if err := c.ForceDelete("payments", "data", time.Second); err != nil {
+		t.Fatalf("ForceDelete returned an error: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().PersistentVolumeClaims("payments").Get("data", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected the PVC to be deleted")
+	}
+	pv, err := clientset.CoreV1().PersistentVolumes().Get("pv-data", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the bound PV to still exist with a relaxed reclaim policy, got error: %v", err)
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy != apiv1.PersistentVolumeReclaimDelete {
+		t.Fatalf("expected the PV's reclaim policy to be relaxed to Delete, got %v", pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+}
+
+func TestPVCCleanerForceDeleteClearsFinalizer(t *testing.T) {
+	withFastPollInterval(t)
+	clientset := fake.NewSimpleClientset(&apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "data",
+			Namespace:  "payments",
+			Finalizers: []string{"kubernetes.io/pvc-protection"},
+		},
+	})
+	blockDeleteWhileFinalized(clientset, pvcGVR)
+	deleteOnFinalizersCleared(clientset, pvcGVR)
+
+	c := &pvcCleaner{clientset: clientset}
+	if err := c.ForceDelete("payments", "data", time.Second); err != nil {
+		t.Fatalf("ForceDelete returned an error: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().PersistentVolumeClaims("payments").Get("data", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected the finalizer-blocked PVC to eventually be deleted")
+	}
+}
+
+func TestPVCCleanerForceDeleteTimesOut(t *testing.T) {
+	withFastPollInterval(t)
+	clientset := fake.NewSimpleClientset(&apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "data",
+			Namespace:  "payments",
+			Finalizers: []string{"kubernetes.io/pvc-protection"},
+		},
+	})
+	blockDeleteWhileFinalized(clientset, pvcGVR)
+
+	c := &pvcCleaner{clientset: clientset}
+	err := c.ForceDelete("payments", "data", 0)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func init() {
+	Register("pvs", func(clientset kubernetes.Interface) ResourceCleaner {
+		return &pvCleaner{clientset: clientset}
+	})
+}
+
+// pvCleaner cleans up PersistentVolumes left behind by a namespace's PVCs.
+// PersistentVolumes are cluster-scoped, so List treats namespace as the
+// namespace of the volume's ClaimRef rather than the volume's own
+// namespace.
+type pvCleaner struct {
+	clientset kubernetes.Interface
+}
+
+func (c *pvCleaner) Kind() string {
+	return "PersistentVolume"
+}
+
+func (c *pvCleaner) List(namespace string) ([]Resource, error) {
+	pvs, err := c.clientset.CoreV1().PersistentVolumes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, pv := range pvs.Items {
+		if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Namespace == namespace {
+			resources = append(resources, Resource{Name: pv.Name})
+		}
+	}
+	return resources, nil
+}
+
+func (c *pvCleaner) Delete(namespace, name string) error {
+	return c.clientset.CoreV1().PersistentVolumes().Delete(name, &metav1.DeleteOptions{})
+}
+
+// ForceDelete clears a Retain reclaim policy (so the delete actually takes
+// effect instead of leaving the volume Released) and removes any
+// finalizers blocking deletion, then waits up to timeout for the volume to
+// disappear.
+func (c *pvCleaner) ForceDelete(namespace, name string, timeout time.Duration) error {
+	if err := c.allowDelete(name); err != nil {
+		return err
+	}
+	if err := c.Delete(namespace, name); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return c.clearFinalizersAndWait(name, timeout)
+}
+
+// allowDelete patches a Retain reclaim policy to Delete so the underlying
+// storage is actually reclaimed once the volume is removed.
+func (c *pvCleaner) allowDelete(name string) error {
+	pv, err := c.clientset.CoreV1().PersistentVolumes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy == apiv1.PersistentVolumeReclaimDelete {
+		return nil
+	}
+	pv.Spec.PersistentVolumeReclaimPolicy = apiv1.PersistentVolumeReclaimDelete
+	_, err = c.clientset.CoreV1().PersistentVolumes().Update(pv)
+	return err
+}
+
+func (c *pvCleaner) clearFinalizersAndWait(name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		pv, err := c.clientset.CoreV1().PersistentVolumes().Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(pv.Finalizers) > 0 {
+			pv.Finalizers = nil
+			if _, err := c.clientset.CoreV1().PersistentVolumes().Update(pv); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for PersistentVolume %s to be deleted", name)
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPVCleanerForceDeleteNormalPath(t *testing.T) {
+	withFastPollInterval(t)
+	clientset := fake.NewSimpleClientset(&apiv1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+		Spec: apiv1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: apiv1.PersistentVolumeReclaimRetain,
+		},
+	})
+
+	c := &pvCleaner{clientset: clientset}
+	if err := c.ForceDelete("payments", "pv-data", time.Second); err != nil {
+		t.Fatalf("ForceDelete returned an error: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().PersistentVolumes().Get("pv-data", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected the PersistentVolume to be deleted")
+	}
+}
+
+func TestPVCleanerForceDeleteClearsFinalizer(t *testing.T) {
+	withFastPollInterval(t)
+	clientset := fake.NewSimpleClientset(&apiv1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "pv-data",
+			Finalizers: []string{"kubernetes.io/pv-protection"},
+		},
+		Spec: apiv1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: apiv1.PersistentVolumeReclaimRetain,
+		},
+	})
+	blockDeleteWhileFinalized(clientset, pvGVR)
+	deleteOnFinalizersCleared(clientset, pvGVR)
+
+	c := &pvCleaner{clientset: clientset}
+	if err := c.ForceDelete("payments", "pv-data", time.Second); err != nil {
+		t.Fatalf("ForceDelete returned an error: %v", err)
+	}
+
+	if _, err := clientset.CoreV1().PersistentVolumes().Get("pv-data", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected the finalizer-blocked PersistentVolume to eventually be deleted")
+	}
+}
+
+func TestPVCleanerForceDeleteTimesOut(t *testing.T) {
+	withFastPollInterval(t)
+	clientset := fake.NewSimpleClientset(&apiv1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "pv-data",
+			Finalizers: []string{"kubernetes.io/pv-protection"},
+		},
+		Spec: apiv1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: apiv1.PersistentVolumeReclaimRetain,
+		},
+	})
+	blockDeleteWhileFinalized(clientset, pvGVR)
+
+	c := &pvCleaner{clientset: clientset}
+	err := c.ForceDelete("payments", "pv-data", 0)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
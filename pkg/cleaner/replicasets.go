@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleaner
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func init() {
+	Register("replicasets", func(clientset kubernetes.Interface) ResourceCleaner {
+		return &replicaSetCleaner{clientset: clientset}
+	})
+}
+
+type replicaSetCleaner struct {
+	clientset kubernetes.Interface
+}
+
+func (c *replicaSetCleaner) Kind() string {
+	return "ReplicaSet"
+}
+
+func (c *replicaSetCleaner) List(namespace string) ([]Resource, error) {
+	replicaSets, err := c.clientset.AppsV1().ReplicaSets(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, replicaSet := range replicaSets.Items {
+		resources = append(resources, Resource{Name: replicaSet.Name})
+	}
+	return resources, nil
+}
+
+func (c *replicaSetCleaner) Delete(namespace, name string) error {
+	return c.clientset.AppsV1().ReplicaSets(namespace).Delete(name, &metav1.DeleteOptions{})
+}
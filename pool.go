@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/viglesiasce/cleanpvcs/pkg/cleaner"
+)
+
+// deleteBackoff bounds the exponential backoff used to retry a failed
+// list/delete call against the API server.
+var deleteBackoff = wait.Backoff{
+	Duration: 100 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+	Steps:    5,
+}
+
+// retriable reports whether err is worth retrying; a missing object is a
+// terminal outcome (someone else already deleted it), not a transient
+// failure.
+func retriable(err error) bool {
+	return err != nil && !apierrors.IsNotFound(err)
+}
+
+// withRetry rate-limits and retries fn with exponential backoff, treating a
+// NotFound error as success rather than exhausting the retry budget on it.
+func withRetry(limiter flowcontrol.RateLimiter, fn func() error) error {
+	err := retry.OnError(deleteBackoff, retriable, func() error {
+		limiter.Accept()
+		return fn()
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// namespaceOutcome pairs one namespace's report with any error encountered
+// while processing it.
+type namespaceOutcome struct {
+	namespace string
+	report    NamespaceReport
+	err       error
+}
+
+// processNamespacesConcurrently fans the given orphaned namespaces out
+// across parallelism workers, each rate-limited by limiter. Unlike the
+// original serial, panic-on-first-error loop, a failure processing one
+// namespace is recorded and does not stop the others.
+func processNamespacesConcurrently(clientset kubernetes.Interface, cleaners []cleaner.ResourceCleaner, namespaces []string, dryRun, force bool, forceTimeout time.Duration, parallelism int, limiter flowcontrol.RateLimiter) ([]NamespaceReport, map[string]error) {
+	jobs := make(chan string)
+	outcomes := make(chan namespaceOutcome)
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for namespace := range jobs {
+				report, err := processOrphanedNamespace(clientset, cleaners, namespace, dryRun, force, forceTimeout, limiter)
+				outcomes <- namespaceOutcome{namespace: namespace, report: report, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, namespace := range namespaces {
+			jobs <- namespace
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	var reports []NamespaceReport
+	errs := make(map[string]error)
+	for outcome := range outcomes {
+		if outcome.err != nil {
+			errs[outcome.namespace] = outcome.err
+			continue
+		}
+		reports = append(reports, outcome.report)
+	}
+	return reports, errs
+}
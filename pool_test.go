@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/viglesiasce/cleanpvcs/pkg/cleaner"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// stubCleaner is a ResourceCleaner whose List/Delete behavior is supplied
+// by the test.
+type stubCleaner struct {
+	kind     string
+	listFn   func(namespace string) ([]cleaner.Resource, error)
+	deleteFn func(namespace, name string) error
+}
+
+func (s *stubCleaner) Kind() string { return s.kind }
+
+func (s *stubCleaner) List(namespace string) ([]cleaner.Resource, error) {
+	return s.listFn(namespace)
+}
+
+func (s *stubCleaner) Delete(namespace, name string) error {
+	return s.deleteFn(namespace, name)
+}
+
+func withFastBackoff(t *testing.T) {
+	t.Helper()
+	original := deleteBackoff
+	deleteBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1.0, Steps: 3}
+	t.Cleanup(func() { deleteBackoff = original })
+}
+
+func TestWithRetryTreatsNotFoundAsSuccess(t *testing.T) {
+	withFastBackoff(t)
+	calls := 0
+	err := withRetry(flowcontrol.NewFakeAlwaysRateLimiter(), func() error {
+		calls++
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "pvcs"}, "missing")
+	})
+	if err != nil {
+		t.Fatalf("expected NotFound to be treated as success, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a NotFound to short-circuit retries, fn was called %d times", calls)
+	}
+}
+
+func TestWithRetryRetriesTransientErrors(t *testing.T) {
+	withFastBackoff(t)
+	calls := 0
+	err := withRetry(flowcontrol.NewFakeAlwaysRateLimiter(), func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient failure")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts before success, got %d", calls)
+	}
+}
+
+func TestWithRetryReturnsPersistentError(t *testing.T) {
+	withFastBackoff(t)
+	want := errors.New("still broken")
+	err := withRetry(flowcontrol.NewFakeAlwaysRateLimiter(), func() error {
+		return want
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("expected the persistent error to surface, got %v", err)
+	}
+}
+
+func TestProcessNamespacesConcurrentlyIsolatesFailures(t *testing.T) {
+	withFastBackoff(t)
+
+	namespaces := []string{"ns-a", "ns-b", "ns-fail"}
+	cleaners := []cleaner.ResourceCleaner{
+		&stubCleaner{
+			kind: "Widget",
+			listFn: func(namespace string) ([]cleaner.Resource, error) {
+				if namespace == "ns-fail" {
+					return nil, errors.New("list failed")
+				}
+				return []cleaner.Resource{{Name: "thing-1"}}, nil
+			},
+			deleteFn: func(namespace, name string) error { return nil },
+		},
+	}
+
+	clientset := fake.NewSimpleClientset()
+	reports, errs := processNamespacesConcurrently(clientset, cleaners, namespaces, false, false, time.Second, 2, flowcontrol.NewFakeAlwaysRateLimiter())
+
+	if len(errs) != 1 || errs["ns-fail"] == nil {
+		t.Fatalf("expected exactly one recorded error for ns-fail, got %v", errs)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("expected the two successful namespaces to still be reported, got %d", len(reports))
+	}
+	seen := make(map[string]bool)
+	for _, report := range reports {
+		seen[report.Namespace] = true
+		if len(report.Results) != 1 || len(report.Results[0].Deleted) != 1 {
+			t.Fatalf("expected namespace %s to have cleaned one Widget, got %+v", report.Namespace, report.Results)
+		}
+	}
+	if !seen["ns-a"] || !seen["ns-b"] {
+		t.Fatalf("expected reports for ns-a and ns-b, got %v", reports)
+	}
+
+	// ns-a and ns-b succeeded end to end, so they should have been
+	// recreated and then deleted again. ns-fail failed partway through
+	// cleanup, so it's left behind for an operator to investigate.
+	for _, namespace := range []string{"ns-a", "ns-b"} {
+		if _, err := clientset.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{}); err == nil {
+			t.Fatalf("expected namespace %s to be recreated then deleted, but it still exists", namespace)
+		}
+	}
+	if _, err := clientset.CoreV1().Namespaces().Get("ns-fail", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected ns-fail to still exist after a failed cleanup, got error: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// defaultProtectedNamespaces are never recreated or deleted, even if a PVC
+// is found orphaned in them, unless the operator overrides the set with
+// --protected-namespaces.
+var defaultProtectedNamespaces = []string{"kube-system", "kube-public", "kube-node-lease", "default"}
+
+// namespaceNameLabel is the well-known label every namespace carries with
+// its own name; it lets a label selector express "match namespaces by
+// name" without requiring the (possibly already-deleted) Namespace object.
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// scopeFilter decides which orphaned namespaces the tool is allowed to
+// touch, combining a namespace selector, an include/exclude allowlist, and
+// a hard-coded protected set.
+type scopeFilter struct {
+	namespaceSelector labels.Selector
+	include           map[string]bool
+	exclude           map[string]bool
+	protected         map[string]bool
+}
+
+// newScopeFilter builds a scopeFilter from the tool's --namespace-selector,
+// --include-namespace, --exclude-namespace, and --protected-namespaces
+// flag values.
+func newScopeFilter(namespaceSelectorExpr, includeCSV, excludeCSV, protectedCSV string) (*scopeFilter, error) {
+	selector, err := labels.Parse(namespaceSelectorExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --namespace-selector: %w", err)
+	}
+	return &scopeFilter{
+		namespaceSelector: selector,
+		include:           toSet(includeCSV),
+		exclude:           toSet(excludeCSV),
+		protected:         toSet(protectedCSV),
+	}, nil
+}
+
+// splitCSV splits a comma-separated flag value, trimming whitespace around
+// each entry and dropping empty ones, so "a, b" and "a,b" parse the same.
+func splitCSV(csv string) []string {
+	var values []string
+	for _, value := range strings.Split(csv, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func toSet(csv string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range splitCSV(csv) {
+		set[name] = true
+	}
+	return set
+}
+
+// allows reports whether namespace is in scope: not protected, not
+// excluded, in the include list if one was given, and matched by the
+// namespace selector.
+func (s *scopeFilter) allows(namespace string) bool {
+	if s.protected[namespace] {
+		return false
+	}
+	if s.exclude[namespace] {
+		return false
+	}
+	if len(s.include) > 0 && !s.include[namespace] {
+		return false
+	}
+	return s.namespaceSelector.Matches(labels.Set{namespaceNameLabel: namespace})
+}
+
+// confirmNamespaces lists the namespaces about to be recreated and cleaned
+// up and, unless yes is set, blocks for an interactive y/N confirmation. It
+// returns false if the operator declined.
+func confirmNamespaces(namespaces []string, yes bool) bool {
+	if len(namespaces) == 0 {
+		fmt.Println("No orphaned namespaces in scope.")
+		return true
+	}
+
+	fmt.Printf("The following %d namespace(s) will be recreated and cleaned up:\n", len(namespaces))
+	for _, name := range namespaces {
+		fmt.Printf("  - %s\n", name)
+	}
+
+	if yes {
+		return true
+	}
+
+	fmt.Print("Proceed? [y/N]: ")
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
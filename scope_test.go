@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestScopeFilterAllows(t *testing.T) {
+	tests := []struct {
+		name              string
+		namespaceSelector string
+		include           string
+		exclude           string
+		protected         string
+		namespace         string
+		want              bool
+	}{
+		{
+			name:      "default protected namespace is blocked",
+			protected: "kube-system,kube-public,kube-node-lease,default",
+			namespace: "kube-system",
+			want:      false,
+		},
+		{
+			name:      "unprotected namespace with no other scoping is allowed",
+			protected: "kube-system,kube-public,kube-node-lease,default",
+			namespace: "payments",
+			want:      true,
+		},
+		{
+			name:      "excluded namespace is blocked even if not protected",
+			exclude:   "payments",
+			namespace: "payments",
+			want:      false,
+		},
+		{
+			name:      "include list only allows listed namespaces",
+			include:   "payments, billing",
+			namespace: "checkout",
+			want:      false,
+		},
+		{
+			name:      "include list allows a listed namespace",
+			include:   "payments, billing",
+			namespace: "billing",
+			want:      true,
+		},
+		{
+			name:              "namespace selector matching kubernetes.io/metadata.name allows a match",
+			namespaceSelector: "kubernetes.io/metadata.name=payments",
+			namespace:         "payments",
+			want:              true,
+		},
+		{
+			name:              "namespace selector rejects a non-matching namespace",
+			namespaceSelector: "kubernetes.io/metadata.name=payments",
+			namespace:         "billing",
+			want:              false,
+		},
+		{
+			name:      "exclude wins over include",
+			include:   "payments",
+			exclude:   "payments",
+			namespace: "payments",
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newScopeFilter(tt.namespaceSelector, tt.include, tt.exclude, tt.protected)
+			if err != nil {
+				t.Fatalf("newScopeFilter returned an error: %v", err)
+			}
+			if got := filter.allows(tt.namespace); got != tt.want {
+				t.Errorf("allows(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitCSVTrimsWhitespace(t *testing.T) {
+	got := splitCSV("deployments, statefulsets ,, pvcs")
+	want := []string{"deployments", "statefulsets", "pvcs"}
+	if len(got) != len(want) {
+		t.Fatalf("splitCSV returned %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("splitCSV returned %v, want %v", got, want)
+		}
+	}
+}